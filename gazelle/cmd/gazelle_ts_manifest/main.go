@@ -0,0 +1,93 @@
+// Command gazelle_ts_manifest regenerates a `ts_manifest` file from a pnpm
+// lockfile, mapping every third-party npm package it declares (including
+// devDependencies, since TS source and test files alike may import them) to
+// a Bazel label under a configurable npm repository prefix.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/robin-pham/rules_js/gazelle/manifest"
+)
+
+type pnpmLockfile struct {
+	Importers map[string]struct {
+		Dependencies map[string]struct {
+			Version string `yaml:"version"`
+		} `yaml:"dependencies"`
+		DevDependencies map[string]struct {
+			Version string `yaml:"version"`
+		} `yaml:"devDependencies"`
+	} `yaml:"importers"`
+	Dependencies map[string]struct {
+		Version string `yaml:"version"`
+	} `yaml:"dependencies"`
+	DevDependencies map[string]struct {
+		Version string `yaml:"version"`
+	} `yaml:"devDependencies"`
+}
+
+func main() {
+	lockfilePath := flag.String("lockfile", "pnpm-lock.yaml", "path to the pnpm-lock.yaml to generate the manifest from")
+	packageJSONPath := flag.String("package_json", "package.json", "path to the package.json the lockfile was generated from")
+	manifestPath := flag.String("manifest", "gazelle_ts.yaml", "path to write the generated ts_manifest file to")
+	npmRepository := flag.String("npm_repository", "npm", "name of the npm_translate_lock repository providing third-party packages")
+	flag.Parse()
+
+	if err := run(*lockfilePath, *packageJSONPath, *manifestPath, *npmRepository); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(lockfilePath, packageJSONPath, manifestPath, npmRepository string) error {
+	content, err := os.ReadFile(lockfilePath)
+	if err != nil {
+		return fmt.Errorf("reading lockfile %q: %w", lockfilePath, err)
+	}
+
+	var lockfile pnpmLockfile
+	if err := yaml.Unmarshal(content, &lockfile); err != nil {
+		return fmt.Errorf("parsing lockfile %q: %w", lockfilePath, err)
+	}
+
+	m := manifest.New()
+	for name := range lockfile.Dependencies {
+		m.ModulesMapping[name] = fmt.Sprintf("@%s//:node_modules/%s", npmRepository, name)
+	}
+	for name := range lockfile.DevDependencies {
+		m.ModulesMapping[name] = fmt.Sprintf("@%s//:node_modules/%s", npmRepository, name)
+	}
+	for _, importer := range lockfile.Importers {
+		for name := range importer.Dependencies {
+			m.ModulesMapping[name] = fmt.Sprintf("@%s//:node_modules/%s", npmRepository, name)
+		}
+		for name := range importer.DevDependencies {
+			m.ModulesMapping[name] = fmt.Sprintf("@%s//:node_modules/%s", npmRepository, name)
+		}
+	}
+
+	integrity, err := manifest.ComputeIntegrity(lockfilePath, packageJSONPath)
+	if err != nil {
+		return err
+	}
+	m.Integrity = integrity
+
+	if err := m.WriteToFile(manifestPath); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(m.ModulesMapping))
+	for name := range m.ModulesMapping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Printf("wrote %d package mappings to %s\n", len(names), manifestPath)
+
+	return nil
+}
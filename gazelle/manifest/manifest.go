@@ -0,0 +1,169 @@
+// Package manifest implements the `ts_manifest` gazelle manifest format: a
+// file mapping npm module names to the Bazel labels that provide them, along
+// with an integrity hash that lets gazelle detect a manifest that has drifted
+// out of sync with the lockfile it was generated from. It mirrors the
+// `gazelle/manifest` package in rules_python.
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is the parsed representation of a `ts_manifest` file.
+type Manifest struct {
+	// ModulesMapping maps an npm module name, including scoped packages
+	// (`@scope/name`) and deep imports (`lodash/fp`), to the Bazel label that
+	// provides it.
+	ModulesMapping map[string]string `json:"modules_mapping" yaml:"modules_mapping"`
+	// Integrity is the sha256 of the lockfile(s) the manifest was generated
+	// from, hex encoded. It is checked against the current lockfile(s) at
+	// load time so a manifest that is out of date fails the build instead of
+	// silently resolving imports incorrectly.
+	Integrity string `json:"integrity" yaml:"integrity"`
+}
+
+// New returns an empty Manifest.
+func New() *Manifest {
+	return &Manifest{
+		ModulesMapping: make(map[string]string),
+	}
+}
+
+// FromFile reads and parses a manifest from path. The format (JSON or YAML)
+// is inferred from the file extension.
+func FromFile(path string) (*Manifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest %q: %w", path, err)
+	}
+
+	m := New()
+	switch filepath.Ext(path) {
+	case ".json":
+		if err := json.Unmarshal(content, m); err != nil {
+			return nil, fmt.Errorf("parsing manifest %q: %w", path, err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(content, m); err != nil {
+			return nil, fmt.Errorf("parsing manifest %q: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("parsing manifest %q: unrecognized extension %q, want .json, .yaml or .yml", path, filepath.Ext(path))
+	}
+
+	if m.ModulesMapping == nil {
+		m.ModulesMapping = make(map[string]string)
+	}
+
+	return m, nil
+}
+
+// FromFileAndVerify reads and parses a manifest from path like FromFile, then
+// verifies its integrity hash against lockfilePaths, failing the build when
+// the manifest is out of date with the lockfile(s) it was generated from.
+func FromFileAndVerify(path string, lockfilePaths ...string) (*Manifest, error) {
+	m, err := FromFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.VerifyIntegrity(path, lockfilePaths...); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// WriteToFile serializes the manifest to path, choosing JSON or YAML based on
+// the file extension.
+func (m *Manifest) WriteToFile(path string) error {
+	var content []byte
+	var err error
+
+	switch filepath.Ext(path) {
+	case ".json":
+		content, err = json.MarshalIndent(m, "", "  ")
+	case ".yaml", ".yml":
+		content, err = yaml.Marshal(m)
+	default:
+		return fmt.Errorf("writing manifest %q: unrecognized extension %q, want .json, .yaml or .yml", path, filepath.Ext(path))
+	}
+	if err != nil {
+		return fmt.Errorf("serializing manifest: %w", err)
+	}
+
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("writing manifest %q: %w", path, err)
+	}
+
+	return nil
+}
+
+// FindLabel resolves modName to a Bazel label, matching deep imports
+// (`lodash/fp/map`) against the longest registered prefix (`lodash/fp`, then
+// `lodash`) when there is no exact entry.
+func (m *Manifest) FindLabel(modName string) (string, bool) {
+	if label, ok := m.ModulesMapping[modName]; ok {
+		return label, true
+	}
+
+	parts := strings.Split(modName, "/")
+	for i := len(parts) - 1; i > 0; i-- {
+		prefix := strings.Join(parts[:i], "/")
+		if label, ok := m.ModulesMapping[prefix]; ok {
+			return label, true
+		}
+	}
+
+	return "", false
+}
+
+// VerifyIntegrity recomputes the sha256 across the given lockfile paths (see
+// ComputeIntegrity) and compares it against m.Integrity, returning an error naming the
+// stale manifest when they differ.
+func (m *Manifest) VerifyIntegrity(manifestPath string, lockfilePaths ...string) error {
+	integrity, err := ComputeIntegrity(lockfilePaths...)
+	if err != nil {
+		return err
+	}
+
+	if integrity != m.Integrity {
+		return fmt.Errorf("manifest %q is out of date with %s, re-run gazelle_ts_manifest to regenerate it", manifestPath, strings.Join(lockfilePaths, ", "))
+	}
+
+	return nil
+}
+
+// ComputeIntegrity returns the hex encoded sha256 across the concatenated
+// contents of paths, taken in sorted path order rather than the order given.
+func ComputeIntegrity(paths ...string) (string, error) {
+	// Sort a copy so the hash is stable regardless of the order callers pass
+	// paths in, while still hashing file contents rather than names.
+	sorted := append([]string(nil), paths...)
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	for _, path := range sorted {
+		f, err := os.Open(path)
+		if err != nil {
+			return "", fmt.Errorf("computing integrity: %w", err)
+		}
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", fmt.Errorf("computing integrity of %q: %w", path, err)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,95 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindLabel(t *testing.T) {
+	m := New()
+	m.ModulesMapping["lodash"] = "@npm//:node_modules/lodash"
+	m.ModulesMapping["@scope/name"] = "@npm//:node_modules/@scope/name"
+
+	tests := []struct {
+		name    string
+		modName string
+		want    string
+		wantOk  bool
+	}{
+		{"exact match", "lodash", "@npm//:node_modules/lodash", true},
+		{"deep import falls back to package", "lodash/fp", "@npm//:node_modules/lodash", true},
+		{"deeper import still falls back", "lodash/fp/map", "@npm//:node_modules/lodash", true},
+		{"scoped package exact match", "@scope/name", "@npm//:node_modules/@scope/name", true},
+		{"deep import of scoped package", "@scope/name/deep", "@npm//:node_modules/@scope/name", true},
+		{"unknown module", "not-in-manifest", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := m.FindLabel(tt.modName)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("FindLabel(%q) = (%q, %v), want (%q, %v)", tt.modName, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestVerifyIntegrity(t *testing.T) {
+	dir := t.TempDir()
+	lockfilePath := filepath.Join(dir, "pnpm-lock.yaml")
+	if err := os.WriteFile(lockfilePath, []byte("lockfile v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	integrity, err := ComputeIntegrity(lockfilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := New()
+	m.Integrity = integrity
+	if err := m.VerifyIntegrity("ts_manifest.json", lockfilePath); err != nil {
+		t.Errorf("VerifyIntegrity() with matching hash returned error: %v", err)
+	}
+
+	if err := os.WriteFile(lockfilePath, []byte("lockfile v2"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.VerifyIntegrity("ts_manifest.json", lockfilePath); err == nil {
+		t.Error("VerifyIntegrity() with a stale lockfile returned nil error, want a staleness error")
+	}
+}
+
+func TestFromFileAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	lockfilePath := filepath.Join(dir, "pnpm-lock.yaml")
+	if err := os.WriteFile(lockfilePath, []byte("lockfile"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	integrity, err := ComputeIntegrity(lockfilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := New()
+	m.ModulesMapping["lodash"] = "@npm//:node_modules/lodash"
+	m.Integrity = integrity
+
+	manifestPath := filepath.Join(dir, "ts_manifest.json")
+	if err := m.WriteToFile(manifestPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := FromFileAndVerify(manifestPath, lockfilePath); err != nil {
+		t.Errorf("FromFileAndVerify() with a fresh manifest returned error: %v", err)
+	}
+
+	if err := os.WriteFile(lockfilePath, []byte("lockfile, but newer"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FromFileAndVerify(manifestPath, lockfilePath); err == nil {
+		t.Error("FromFileAndVerify() with a stale manifest returned nil error, want a staleness error")
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/emirpasic/gods/lists/singlylinkedlist"
+
+	"github.com/robin-pham/rules_js/gazelle/manifest"
 )
 
 type EnvironmentType string
@@ -16,6 +18,22 @@ const (
 	EnvironmentOther   EnvironmentType = "other"
 )
 
+// GenerationMode controls whether the extension is allowed to create new
+// BUILD files, update existing ones, or both.
+type GenerationMode string
+
+const (
+	// GenerationModeCreate only generates new BUILD files; existing ones are
+	// left untouched.
+	GenerationModeCreate GenerationMode = "create"
+	// GenerationModeUpdate creates new BUILD files and merges generated
+	// rules into existing ones. This is the default.
+	GenerationModeUpdate GenerationMode = "update"
+	// GenerationModeUpdateOnly never creates new BUILD files, only refreshes
+	// rules in packages that already have one.
+	GenerationModeUpdateOnly GenerationMode = "update_only"
+)
+
 // Directives
 const (
 	// TypeScriptGenerationDirective represents the directive that controls whether
@@ -40,6 +58,32 @@ const (
 	// naming convention. See ts_project_naming_convention for more info on
 	// the package name interpolation.
 	TestNamingConvention = "ts_test_naming_convention"
+	// ManifestDirective represents the directive that points a Bazel package
+	// at a `ts_manifest` file used to resolve third-party npm dependencies.
+	// Sub-packages inherit this value until overridden.
+	ManifestDirective = "ts_manifest"
+	// DefaultVisibilityDirective represents the directive that controls the
+	// visibility applied to every generated ts_project and npm_package target
+	// in a Bazel package and its sub-packages. The first argument is either
+	// "append", to add to the visibility list inherited from the parent
+	// package, or "replace", to discard it and start over. E.g.
+	// `ts_default_visibility append //foo:__subpackages__`.
+	DefaultVisibilityDirective = "ts_default_visibility"
+	// RootDirective represents the directive that marks a Bazel package as
+	// the base for TypeScript import resolution (baseUrl, tsconfig `paths`,
+	// and bare-specifier lookups), rather than always resolving relative to
+	// the workspace root. Sub-packages inherit the nearest ts_root until a
+	// deeper package sets its own. This mirrors rules_python's python_root.
+	RootDirective = "ts_root"
+	// TSConfigDirective represents the directive that points a Bazel package
+	// at a non-standard tsconfig filename to load compilerOptions.baseUrl,
+	// paths, and rootDirs from. Defaults to "tsconfig.json".
+	TSConfigDirective = "ts_tsconfig"
+	// GenerationModeDirective represents the directive that controls whether
+	// the extension creates new BUILD files, only updates existing ones, or
+	// both. Sub-packages inherit this value. One of "create", "update", or
+	// "update_only". Defaults to "update".
+	GenerationModeDirective = "ts_generation_mode"
 )
 
 const (
@@ -73,6 +117,13 @@ type Config struct {
 	validateImportStatements bool
 	libraryNamingConvention  string
 	testNamingConvention     string
+	manifest                 *manifest.Manifest
+	defaultVisibility        []string
+	useConventions           bool
+	root                     string
+	tsconfigFilename         string
+	resolvedTSConfig         *ResolvedTSConfig
+	generationMode           GenerationMode
 }
 
 // New creates a new Config.
@@ -88,6 +139,13 @@ func New(
 		validateImportStatements: true,
 		libraryNamingConvention:  packageNameNamingConventionSubstitution,
 		testNamingConvention:     fmt.Sprintf("%s_test", packageNameNamingConventionSubstitution),
+		manifest:                 nil,
+		defaultVisibility:        nil,
+		useConventions:           false,
+		root:                     "",
+		tsconfigFilename:         DefaultTSConfigFilename,
+		resolvedTSConfig:         nil,
+		generationMode:           GenerationModeUpdate,
 	}
 }
 
@@ -109,6 +167,13 @@ func (c *Config) NewChild() *Config {
 		validateImportStatements: c.validateImportStatements,
 		libraryNamingConvention:  c.libraryNamingConvention,
 		testNamingConvention:     c.testNamingConvention,
+		manifest:                 nil,
+		defaultVisibility:        c.defaultVisibility,
+		useConventions:           c.useConventions,
+		root:                     c.root,
+		tsconfigFilename:         c.tsconfigFilename,
+		resolvedTSConfig:         c.resolvedTSConfig,
+		generationMode:           c.generationMode,
 	}
 }
 
@@ -133,11 +198,56 @@ func (c *Config) GenerationEnabled() bool {
 	return c.generationEnabled
 }
 
+// SetGenerationMode sets whether the extension may create new BUILD files,
+// only update existing ones, or both.
+func (c *Config) SetGenerationMode(mode GenerationMode) {
+	c.generationMode = mode
+}
+
+// GenerationMode returns the configured generation mode. Defaults to
+// GenerationModeUpdate.
+func (c *Config) GenerationMode() GenerationMode {
+	return c.generationMode
+}
+
+// SetManifest sets the parsed `ts_manifest` for this config, as read from the
+// file pointed at by the ts_manifest directive.
+func (c *Config) SetManifest(m *manifest.Manifest) {
+	c.manifest = m
+}
+
+// Manifest returns the `ts_manifest` configured for this package, if any.
+func (c *Config) Manifest() *manifest.Manifest {
+	return c.manifest
+}
+
+// LoadManifestFile loads and verifies the `ts_manifest` at manifestPath,
+// checking its integrity hash against lockfilePaths (typically
+// package.json and pnpm-lock.yaml) so a manifest left stale after a
+// dependency bump fails the build instead of silently resolving imports to
+// the wrong label.
+func (c *Config) LoadManifestFile(manifestPath string, lockfilePaths ...string) error {
+	m, err := manifest.FromFileAndVerify(manifestPath, lockfilePaths...)
+	if err != nil {
+		return err
+	}
+	c.manifest = m
+	return nil
+}
+
 // FindThirdPartyDependency scans the gazelle manifests for the current config
 // and the parent configs up to the root finding if it can resolve the module
 // name.
 func (c *Config) FindThirdPartyDependency(modName string) (string, bool) {
-	// TODO
+	for cfg := c; cfg != nil; cfg = cfg.parent {
+		if cfg.manifest == nil {
+			continue
+		}
+		if label, ok := cfg.manifest.FindLabel(modName); ok {
+			return label, true
+		}
+	}
+
 	return "", false
 }
 
@@ -207,3 +317,166 @@ func (c *Config) SetTestNamingConvention(testNamingConvention string) {
 func (c *Config) RenderTestName(packageName string) string {
 	return strings.ReplaceAll(c.testNamingConvention, packageNameNamingConventionSubstitution, packageName)
 }
+
+// SetDefaultVisibility replaces the default visibility list applied to
+// generated ts_project and npm_package targets in this package and its
+// sub-packages, discarding whatever was inherited from the parent.
+func (c *Config) SetDefaultVisibility(visibility []string) {
+	c.defaultVisibility = visibility
+}
+
+// AddDefaultVisibility appends to the default visibility list inherited from
+// the parent package. It copies the inherited slice before appending so that
+// sibling packages appending their own entries don't clobber each other's
+// backing array.
+func (c *Config) AddDefaultVisibility(visibility []string) {
+	c.defaultVisibility = append(append([]string(nil), c.defaultVisibility...), visibility...)
+}
+
+// DefaultVisibility returns the visibility list that should be applied to
+// every generated ts_project and npm_package target in this package.
+func (c *Config) DefaultVisibility() []string {
+	return c.defaultVisibility
+}
+
+// SetUseConventions sets whether the extension enforces naming conventions
+// via CheckConvention instead of relying on the cross-package import index.
+// It is controlled by the -use_conventions command line flag.
+func (c *Config) SetUseConventions(useConventions bool) {
+	c.useConventions = useConventions
+}
+
+// UseConventions returns whether naming conventions should be enforced. See
+// SetUseConventions.
+func (c *Config) UseConventions() bool {
+	return c.useConventions
+}
+
+// CheckConvention reports whether name, the target that gazelle discovered
+// of the given kind for import path imp in Bazel package rel, matches this
+// package's naming convention. It only has an effect when UseConventions is
+// enabled; a false result means the caller should synthesize a
+// `# gazelle:resolve js <imp> <label>` directive (see ResolveDirective) into
+// the root BUILD.bazel so imp still resolves when running gazelle with
+// -index=false.
+func (c *Config) CheckConvention(kind, imp, name, rel string) bool {
+	if !c.useConventions {
+		return true
+	}
+
+	switch kind {
+	case "ts_project":
+		// filepath.Base("") returns ".", not "", so special-case the root
+		// Bazel package the way Configs.ParentForPackage does.
+		packageName := ""
+		if rel != "" {
+			packageName = filepath.Base(rel)
+		}
+		return name == c.RenderLibraryName(packageName) || name == c.RenderTestName(packageName)
+	default:
+		return true
+	}
+}
+
+// SetRoot marks rel, the Bazel package this Config was created for, as a
+// ts_root: the base package that TypeScript import resolution (baseUrl,
+// tsconfig `paths`, and bare-specifier lookups) should resolve against
+// instead of the workspace root. The value is inherited by NewChild until a
+// deeper package sets its own.
+func (c *Config) SetRoot(rel string) {
+	c.root = rel
+}
+
+// Root returns the nearest ts_root package for this Config, or "" if none
+// was set, meaning import resolution should use the workspace root.
+func (c *Config) Root() string {
+	return c.root
+}
+
+// SetTSConfigFilename sets the tsconfig filename to load compilerOptions
+// from for this package and its sub-packages, overriding the default
+// "tsconfig.json".
+func (c *Config) SetTSConfigFilename(filename string) {
+	c.tsconfigFilename = filename
+}
+
+// TSConfigFilename returns the tsconfig filename configured for this
+// package. Defaults to "tsconfig.json".
+func (c *Config) TSConfigFilename() string {
+	return c.tsconfigFilename
+}
+
+// LoadTSConfigFile loads and caches the tsconfig file at absPath, following
+// its extends chain, and stores the resolved baseUrl/paths/rootDirs on this
+// Config so ResolvePathAlias and NewChild pick it up.
+func (c *Config) LoadTSConfigFile(absPath string) error {
+	resolved, err := LoadTSConfig(filepath.Join(c.repoRoot, c.Root()), absPath)
+	if err != nil {
+		return err
+	}
+	c.resolvedTSConfig = resolved
+	return nil
+}
+
+// ResolvePathAlias expands importPath against this package's tsconfig
+// `paths` mapping (falling back to a parent's tsconfig via NewChild
+// inheritance), returning the workspace-relative path it resolves to.
+// Resolved paths are anchored at the nearest ts_root (see Root), joined with
+// the directory of the tsconfig.json that defined baseUrl, then baseUrl
+// itself, matching tsc's own resolution rules. It matches the longest
+// registered prefix, so `@app/components/*` is preferred over `@app/*` when
+// both are present. The second return value is false when importPath does
+// not match any configured alias.
+func (c *Config) ResolvePathAlias(importPath string) (string, bool) {
+	if c.resolvedTSConfig == nil {
+		return "", false
+	}
+
+	var bestMatch string
+	var bestSpecificity int
+	found := false
+
+	for pattern, targets := range c.resolvedTSConfig.Paths {
+		if len(targets) == 0 {
+			continue
+		}
+
+		prefix, wildcard, hasWildcard := strings.Cut(pattern, "*")
+		var rest string
+		if hasWildcard {
+			if !strings.HasPrefix(importPath, prefix) || !strings.HasSuffix(importPath, wildcard) {
+				continue
+			}
+			// Prefix and suffix can overlap for a short importPath against a
+			// pattern like "x*x", so guard against a negative-length slice.
+			if len(importPath) < len(prefix)+len(wildcard) {
+				continue
+			}
+			rest = importPath[len(prefix) : len(importPath)-len(wildcard)]
+		} else if importPath != pattern {
+			continue
+		}
+
+		target := targets[0]
+		var resolved string
+		if hasWildcard {
+			resolved = strings.Replace(target, "*", rest, 1)
+		} else {
+			resolved = target
+		}
+		resolved = filepath.Join(c.Root(), c.resolvedTSConfig.Dir, c.resolvedTSConfig.BaseUrl, resolved)
+
+		if specificity := len(prefix); !found || specificity > bestSpecificity {
+			bestMatch, bestSpecificity, found = resolved, specificity, true
+		}
+	}
+
+	return bestMatch, found
+}
+
+// ResolveDirective renders the `# gazelle:resolve js <imp> <label>` directive
+// that should be written to the root BUILD.bazel for an import that failed
+// CheckConvention.
+func ResolveDirective(imp, label string) string {
+	return fmt.Sprintf("# gazelle:resolve js %s %s", imp, label)
+}
@@ -0,0 +1,195 @@
+package tsconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultTSConfigFilename is the tsconfig filename gazelle looks for when no
+// ts_tsconfig directive overrides it.
+const DefaultTSConfigFilename = "tsconfig.json"
+
+// tsConfigFile is the subset of tsconfig.json gazelle cares about for import
+// resolution.
+type tsConfigFile struct {
+	Extends         string `json:"extends"`
+	CompilerOptions struct {
+		BaseUrl  string              `json:"baseUrl"`
+		Paths    map[string][]string `json:"paths"`
+		RootDirs []string            `json:"rootDirs"`
+	} `json:"compilerOptions"`
+}
+
+// ResolvedTSConfig is the effective baseUrl/paths/rootDirs for a package
+// after following its tsconfig.json's `extends` chain.
+type ResolvedTSConfig struct {
+	BaseUrl  string
+	Paths    map[string][]string
+	RootDirs []string
+	// Dir is the directory of the tsconfig.json that set BaseUrl (or, if none
+	// in the chain set one, of the tsconfig.json originally passed to
+	// LoadTSConfig), expressed relative to anchorDir. Paths are resolved
+	// relative to Dir joined with BaseUrl, matching tsc's own resolution
+	// rules.
+	Dir string
+}
+
+// tsconfigCacheKey identifies a parsed tsconfig by both its absolute path
+// and the anchor it was resolved against, since the same tsconfig.json can
+// be reached via `extends` from more than one ts_root and its Dir is
+// anchor-relative.
+type tsconfigCacheKey struct {
+	anchorDir string
+	absPath   string
+}
+
+var (
+	tsconfigCacheMu sync.Mutex
+	tsconfigCache   = make(map[tsconfigCacheKey]*ResolvedTSConfig)
+)
+
+// LoadTSConfig parses the tsconfig.json at absPath, following its `extends`
+// chain (relative or absolute file paths only; package specifiers are ignored
+// since gazelle doesn't resolve into node_modules for this purpose), and
+// returns the merged result. anchorDir is used to express the result's Dir
+// relative to it; callers resolving within a ts_root subtree should pass that
+// root's absolute directory rather than the workspace root, so each
+// independent root resolves imports relative to itself. Parsed files are
+// cached by absolute path and anchorDir, so a tsconfig shared by many
+// packages under the same anchor, or re-visited via an extends chain, is only
+// parsed once per anchor.
+func LoadTSConfig(anchorDir, absPath string) (*ResolvedTSConfig, error) {
+	tsconfigCacheMu.Lock()
+	defer tsconfigCacheMu.Unlock()
+	return loadTSConfigLocked(anchorDir, absPath, nil)
+}
+
+func loadTSConfigLocked(anchorDir, absPath string, seen map[string]bool) (*ResolvedTSConfig, error) {
+	key := tsconfigCacheKey{anchorDir: anchorDir, absPath: absPath}
+	if cached, ok := tsconfigCache[key]; ok {
+		return cached, nil
+	}
+
+	if seen == nil {
+		seen = make(map[string]bool)
+	}
+	if seen[absPath] {
+		return nil, fmt.Errorf("tsconfig %q: circular extends chain", absPath)
+	}
+	seen[absPath] = true
+
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading tsconfig %q: %w", absPath, err)
+	}
+
+	var raw tsConfigFile
+	if err := json.Unmarshal(stripJSONComments(content), &raw); err != nil {
+		return nil, fmt.Errorf("parsing tsconfig %q: %w", absPath, err)
+	}
+
+	selfDir, err := filepath.Rel(anchorDir, filepath.Dir(absPath))
+	if err != nil {
+		return nil, fmt.Errorf("tsconfig %q: not under %q: %w", absPath, anchorDir, err)
+	}
+	if selfDir == "." {
+		selfDir = ""
+	}
+
+	resolved := &ResolvedTSConfig{
+		Paths: make(map[string][]string),
+		Dir:   selfDir,
+	}
+
+	if raw.Extends != "" && (strings.HasPrefix(raw.Extends, ".") || strings.HasPrefix(raw.Extends, "/")) {
+		parentPath := raw.Extends
+		if !filepath.IsAbs(parentPath) {
+			parentPath = filepath.Join(filepath.Dir(absPath), parentPath)
+		}
+		if filepath.Ext(parentPath) == "" {
+			parentPath += ".json"
+		}
+		parent, err := loadTSConfigLocked(anchorDir, parentPath, seen)
+		if err != nil {
+			return nil, err
+		}
+		resolved.BaseUrl = parent.BaseUrl
+		resolved.RootDirs = parent.RootDirs
+		if parent.BaseUrl != "" {
+			resolved.Dir = parent.Dir
+		}
+		for k, v := range parent.Paths {
+			resolved.Paths[k] = v
+		}
+	}
+
+	if raw.CompilerOptions.BaseUrl != "" {
+		resolved.BaseUrl = raw.CompilerOptions.BaseUrl
+		resolved.Dir = selfDir
+	}
+	if len(raw.CompilerOptions.RootDirs) > 0 {
+		resolved.RootDirs = raw.CompilerOptions.RootDirs
+	}
+	for k, v := range raw.CompilerOptions.Paths {
+		resolved.Paths[k] = v
+	}
+
+	tsconfigCache[key] = resolved
+	return resolved, nil
+}
+
+// stripJSONComments removes // and /* */ comments from JSON-with-comments
+// (JSONC) content, as used by tsconfig.json, without disturbing comment-like
+// sequences inside string literals.
+func stripJSONComments(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	escaped := false
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		if inString {
+			out = append(out, c)
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		if c == '"' {
+			inString = true
+			out = append(out, c)
+			continue
+		}
+
+		if c == '/' && i+1 < len(data) && data[i+1] == '/' {
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			out = append(out, '\n')
+			continue
+		}
+
+		if c == '/' && i+1 < len(data) && data[i+1] == '*' {
+			i += 2
+			for i+1 < len(data) && !(data[i] == '*' && data[i+1] == '/') {
+				i++
+			}
+			i++
+			continue
+		}
+
+		out = append(out, c)
+	}
+
+	return out
+}
@@ -0,0 +1,116 @@
+package tsconfig
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadTSConfigBasic(t *testing.T) {
+	dir := t.TempDir()
+	tsconfigPath := filepath.Join(dir, "tsconfig.json")
+	writeFile(t, tsconfigPath, `{
+		// leading comment
+		"compilerOptions": {
+			"baseUrl": "src", /* trailing comment */
+			"paths": {
+				"@app/*": ["app/*"]
+			}
+		}
+	}`)
+
+	resolved, err := LoadTSConfig(dir, tsconfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.BaseUrl != "src" {
+		t.Errorf("BaseUrl = %q, want %q", resolved.BaseUrl, "src")
+	}
+	if resolved.Dir != "" {
+		t.Errorf("Dir = %q, want \"\" for a tsconfig at anchorDir", resolved.Dir)
+	}
+	if got := resolved.Paths["@app/*"]; len(got) != 1 || got[0] != "app/*" {
+		t.Errorf("Paths[@app/*] = %v, want [\"app/*\"]", got)
+	}
+}
+
+func TestLoadTSConfigExtendsChain(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "tsconfig.base.json")
+	writeFile(t, basePath, `{
+		"compilerOptions": {
+			"baseUrl": ".",
+			"paths": { "@base/*": ["base/*"] }
+		}
+	}`)
+
+	childPath := filepath.Join(dir, "pkg", "tsconfig.json")
+	writeFile(t, childPath, `{
+		"extends": "../tsconfig.base.json",
+		"compilerOptions": {
+			"paths": { "@pkg/*": ["pkg/*"] }
+		}
+	}`)
+
+	resolved, err := LoadTSConfig(dir, childPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.BaseUrl != "." {
+		t.Errorf("BaseUrl = %q, want inherited %q", resolved.BaseUrl, ".")
+	}
+	if resolved.Dir != "" {
+		t.Errorf("Dir = %q, want the parent's dir since the child didn't override baseUrl", resolved.Dir)
+	}
+	if _, ok := resolved.Paths["@base/*"]; !ok {
+		t.Error("Paths missing entry inherited from extends chain")
+	}
+	if _, ok := resolved.Paths["@pkg/*"]; !ok {
+		t.Error("Paths missing entry declared by the child itself")
+	}
+}
+
+func TestLoadTSConfigCacheKeyedByAnchor(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "tsconfig.base.json")
+	writeFile(t, basePath, `{
+		"compilerOptions": { "baseUrl": "." }
+	}`)
+
+	aDir := filepath.Join(dir, "packages", "a")
+	aPath := filepath.Join(aDir, "tsconfig.json")
+	writeFile(t, aPath, `{"extends": "../../tsconfig.base.json"}`)
+
+	bDir := filepath.Join(dir, "deep", "nested", "b")
+	bPath := filepath.Join(bDir, "tsconfig.json")
+	writeFile(t, bPath, `{"extends": "../../../tsconfig.base.json"}`)
+
+	// Resolve a's tsconfig first so the shared base gets cached under a's
+	// anchor before b ever asks for it.
+	aResolved, err := LoadTSConfig(aDir, aPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join("..", ".."); aResolved.Dir != want {
+		t.Fatalf("a.Dir = %q, want %q", aResolved.Dir, want)
+	}
+
+	bResolved, err := LoadTSConfig(bDir, bPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join("..", "..", ".."); bResolved.Dir != want {
+		t.Errorf("b.Dir = %q, want %q (a shared extends target must be resolved separately per anchor)", bResolved.Dir, want)
+	}
+}
+
+func TestLoadTSConfigCircularExtends(t *testing.T) {
+	dir := t.TempDir()
+	aPath := filepath.Join(dir, "a.json")
+	bPath := filepath.Join(dir, "b.json")
+	writeFile(t, aPath, `{"extends": "./b.json"}`)
+	writeFile(t, bPath, `{"extends": "./a.json"}`)
+
+	if _, err := LoadTSConfig(dir, aPath); err == nil {
+		t.Error("LoadTSConfig() with a circular extends chain returned nil error")
+	}
+}